@@ -0,0 +1,152 @@
+package goparsec
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Parser is a typed parser: like TextParser, but it produces a value of
+// type T instead of collapsing everything down to the consumed token.
+type Parser[T any] func(string) (T, string, error)
+
+// Map runs p and transforms its token with f, without changing how
+// much input p consumes.
+func Map[T any](p TextParser, f func(string) T) Parser[T] {
+	return func(in string) (T, string, error) {
+		tok, rem, err := p(in)
+		if err != nil {
+			var zero T
+			return zero, in, err
+		}
+		return f(tok), rem, nil
+	}
+}
+
+// Bind runs p, passes its result to f to produce the next parser, and
+// runs that parser against the remaining input. This sequences Parser
+// values when the second parser depends on the first's value, which
+// Map alone can't express.
+func Bind[A, B any](p Parser[A], f func(A) Parser[B]) Parser[B] {
+	return func(in string) (B, string, error) {
+		a, rem, err := p(in)
+		if err != nil {
+			var zero B
+			return zero, in, err
+		}
+		return f(a)(rem)
+	}
+}
+
+// Pair holds the results of two parsers run in sequence.
+type Pair[A, B any] struct {
+	First  A
+	Second B
+}
+
+// Seq2 runs pa then pb and pairs their results.
+func Seq2[A, B any](pa Parser[A], pb Parser[B]) Parser[Pair[A, B]] {
+	return func(in string) (Pair[A, B], string, error) {
+		a, rem, err := pa(in)
+		if err != nil {
+			return Pair[A, B]{}, in, err
+		}
+		b, rem, err := pb(rem)
+		if err != nil {
+			return Pair[A, B]{}, in, err
+		}
+		return Pair[A, B]{a, b}, rem, nil
+	}
+}
+
+// Triple holds the results of three parsers run in sequence.
+type Triple[A, B, C any] struct {
+	First  A
+	Second B
+	Third  C
+}
+
+// Seq3 runs pa, pb, then pc and collects their results.
+func Seq3[A, B, C any](pa Parser[A], pb Parser[B], pc Parser[C]) Parser[Triple[A, B, C]] {
+	return func(in string) (Triple[A, B, C], string, error) {
+		a, rem, err := pa(in)
+		if err != nil {
+			return Triple[A, B, C]{}, in, err
+		}
+		b, rem, err := pb(rem)
+		if err != nil {
+			return Triple[A, B, C]{}, in, err
+		}
+		c, rem, err := pc(rem)
+		if err != nil {
+			return Triple[A, B, C]{}, in, err
+		}
+		return Triple[A, B, C]{a, b, c}, rem, nil
+	}
+}
+
+// Captures holds the named submatches collected by CaptureSeq.
+type Captures map[string]string
+
+// Field pairs a capture name with the parser that should fill it. An
+// empty name runs P for its side effect on the input without recording
+// a submatch, the way And silently includes unnamed structure today.
+type Field struct {
+	Name string
+	P    TextParser
+}
+
+// Capture names a TextParser's token so CaptureSeq can collect it,
+// without changing what the parser matches.
+func Capture(name string, p TextParser) Field {
+	return Field{Name: name, P: p}
+}
+
+// CaptureSeq runs each field's parser in order, the way And does, but
+// collects the matched token of every named field into a Captures map
+// instead of concatenating them all into one token.
+func CaptureSeq(fields ...Field) Parser[Captures] {
+	return func(in string) (Captures, string, error) {
+		caps := make(Captures, len(fields))
+		rem := in
+		for _, f := range fields {
+			tok, r, err := f.P(rem)
+			if err != nil {
+				return nil, in, err
+			}
+			if f.Name != "" {
+				caps[f.Name] = tok
+			}
+			rem = r
+		}
+		return caps, rem, nil
+	}
+}
+
+// Unmarshal copies caps into the exported string fields of the struct
+// pointed to by dst that are tagged `parsec:"name"`, matching each tag
+// against a key in caps. Fields with no matching capture are left
+// untouched.
+func Unmarshal(caps Captures, dst any) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("goparsec: Unmarshal requires a pointer to a struct, got %T", dst)
+	}
+	v = v.Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		tag := t.Field(i).Tag.Get("parsec")
+		if tag == "" {
+			continue
+		}
+		val, ok := caps[tag]
+		if !ok {
+			continue
+		}
+		field := v.Field(i)
+		if field.Kind() != reflect.String || !field.CanSet() {
+			return fmt.Errorf("goparsec: field %s must be a settable string to receive capture %q", t.Field(i).Name, tag)
+		}
+		field.SetString(val)
+	}
+	return nil
+}
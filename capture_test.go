@@ -0,0 +1,90 @@
+package goparsec
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestMap(t *testing.T) {
+	digits := Map(Repeat(ExpectDigit), func(s string) int {
+		n, _ := strconv.Atoi(s)
+		return n
+	})
+	n, rem, err := digits("42abc")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != 42 || rem != "abc" {
+		t.Errorf("got (%d, %q), want (42, %q)", n, rem, "abc")
+	}
+}
+
+func TestBind(t *testing.T) {
+	digits := Map(Repeat(ExpectDigit), func(s string) int {
+		n, _ := strconv.Atoi(s)
+		return n
+	})
+	// Parses a number n, then exactly that many 'x' characters.
+	p := Bind(digits, func(n int) Parser[string] {
+		return Map(Times(n, ExpectRune('x')), func(s string) string { return s })
+	})
+
+	tok, rem, err := p("3xxxy")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tok != "xxx" || rem != "y" {
+		t.Errorf("got (%q, %q), want (%q, %q)", tok, rem, "xxx", "y")
+	}
+
+	_, _, err = p("3xxy")
+	if err == nil {
+		t.Fatalf("expected an error when fewer than n 'x's are present")
+	}
+}
+
+func TestSeq2(t *testing.T) {
+	toInt := func(s string) int { n, _ := strconv.Atoi(s); return n }
+	p := Seq2(Map(Repeat(ExpectDigit), toInt), Map(ExpectByte(','), func(s string) string { return s }))
+
+	pair, rem, err := p("12,rest")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pair.First != 12 || pair.Second != "," || rem != "rest" {
+		t.Errorf("got (%v, %q), want (12, %q) rest %q", pair, rem, ",", "rest")
+	}
+}
+
+func TestCaptureSeqAndUnmarshal(t *testing.T) {
+	p := CaptureSeq(
+		Capture("key", Repeat(ExpectLetter)),
+		Field{P: ExpectByte('=')},
+		Capture("value", Repeat(ExpectDigit)),
+	)
+
+	caps, rem, err := p("count=42;")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if caps["key"] != "count" || caps["value"] != "42" || rem != ";" {
+		t.Errorf("got caps=%v rem=%q", caps, rem)
+	}
+
+	var kv struct {
+		Key   string `parsec:"key"`
+		Value string `parsec:"value"`
+	}
+	if err := Unmarshal(caps, &kv); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if kv.Key != "count" || kv.Value != "42" {
+		t.Errorf("got %+v, want Key=count Value=42", kv)
+	}
+}
+
+func TestUnmarshalRequiresPointerToStruct(t *testing.T) {
+	if err := Unmarshal(Captures{"a": "1"}, struct{}{}); err == nil {
+		t.Fatalf("expected an error when dst is not a pointer to a struct")
+	}
+}
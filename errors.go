@@ -0,0 +1,371 @@
+package goparsec
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// ParseError is a structured parse failure. It carries the byte offset,
+// the 1-based line and column that offset falls on, the label of the
+// production that failed (if any), and the set of things that would have
+// been accepted at that position.
+type ParseError struct {
+	Offset   int
+	Line     int
+	Col      int
+	Label    string
+	Expected []string
+	Got      string
+}
+
+func (e *ParseError) Error() string {
+	got := e.Got
+	if got == "" {
+		got = "EOF"
+	}
+	switch len(e.Expected) {
+	case 0:
+		return fmt.Sprintf("line %d col %d: unexpected %s", e.Line, e.Col, got)
+	case 1:
+		return fmt.Sprintf("line %d col %d: expected %s, got %s", e.Line, e.Col, e.Expected[0], got)
+	default:
+		return fmt.Sprintf("line %d col %d: expected one of {%s}, got %s", e.Line, e.Col, strings.Join(e.Expected, ", "), got)
+	}
+}
+
+// newParseError builds a ParseError for a failure at the given offset,
+// naming what was found (got) and what would have been accepted
+// (expected).
+func newParseError(offset int, got string, expected ...string) *ParseError {
+	return &ParseError{Offset: offset, Got: got, Expected: expected}
+}
+
+// Resolve fills in Line and Col by locating e.Offset within the original
+// top-level input.
+func (e *ParseError) Resolve(input string) *ParseError {
+	e.Line, e.Col = lineCol(input, e.Offset)
+	return e
+}
+
+// lineCol returns the 1-based line and column of the given byte offset
+// into s.
+func lineCol(s string, offset int) (line, col int) {
+	if offset > len(s) {
+		offset = len(s)
+	}
+	line, col = 1, 1
+	for _, r := range s[:offset] {
+		if r == '\n' {
+			line++
+			col = 1
+		} else {
+			col++
+		}
+	}
+	return line, col
+}
+
+// mergeExpected implements the longest-match rule: only the failures
+// that got furthest into the input are reported, with their
+// expected-sets unioned.
+func mergeExpected(errs []*ParseError, offset int, in string) *ParseError {
+	if len(errs) == 0 {
+		got := "EOF"
+		if len(in) > 0 {
+			r, _ := utf8.DecodeRuneInString(in)
+			got = string(r)
+		}
+		return newParseError(offset, got)
+	}
+
+	furthest := errs[0].Offset
+	for _, e := range errs[1:] {
+		if e.Offset > furthest {
+			furthest = e.Offset
+		}
+	}
+
+	seen := make(map[string]struct{})
+	var expected []string
+	got := ""
+	for _, e := range errs {
+		if e.Offset != furthest {
+			continue
+		}
+		got = e.Got
+		for _, x := range e.Expected {
+			if _, ok := seen[x]; !ok {
+				seen[x] = struct{}{}
+				expected = append(expected, x)
+			}
+		}
+	}
+	sort.Strings(expected)
+
+	return newParseError(furthest, got, expected...)
+}
+
+// TextParserPos is like TextParser but threads a byte offset through the
+// parse, so that failures can be reported with a position instead of a
+// bare error.
+type TextParserPos func(in string, offset int) (tok string, rem string, newOffset int, err error)
+
+// FromTextParser adapts a TextParser into a TextParserPos, advancing the
+// offset by the length of the consumed token.
+func FromTextParser(p TextParser) TextParserPos {
+	return func(in string, offset int) (string, string, int, error) {
+		tok, rem, err := p(in)
+		if err != nil {
+			return tok, rem, offset, wrapPosError(offset, in, err)
+		}
+		return tok, rem, offset + len(tok), nil
+	}
+}
+
+func wrapPosError(offset int, in string, err error) error {
+	if pe, ok := err.(*ParseError); ok {
+		pe.Offset += offset
+		return pe
+	}
+	got := "EOF"
+	if len(in) > 0 {
+		r, _ := utf8.DecodeRuneInString(in)
+		got = string(r)
+	}
+	return newParseError(offset, got, err.Error())
+}
+
+// ToTextParser adapts a TextParserPos back into a plain TextParser,
+// running it from offset 0 and resolving any *ParseError against the
+// full input.
+func ToTextParser(p TextParserPos) TextParser {
+	return func(in string) (string, string, error) {
+		tok, rem, _, err := p(in, 0)
+		if err != nil {
+			if pe, ok := err.(*ParseError); ok {
+				pe.Resolve(in)
+			}
+			return tok, rem, err
+		}
+		return tok, rem, nil
+	}
+}
+
+// Label names a production so that a failure inside p is reported using
+// name as the expected thing, instead of whatever message the underlying
+// parser produced.
+func Label(name string, p TextParserPos) TextParserPos {
+	return func(in string, offset int) (string, string, int, error) {
+		tok, rem, newOffset, err := p(in, offset)
+		if err != nil {
+			if pe, ok := err.(*ParseError); ok {
+				pe.Label = name
+				pe.Expected = []string{name}
+			}
+		}
+		return tok, rem, newOffset, err
+	}
+}
+
+// AndPos is the TextParserPos equivalent of And: every parser must
+// succeed in sequence, and the token is the concatenation of their
+// output.
+func AndPos(parsers ...TextParserPos) TextParserPos {
+	return func(in string, offset int) (string, string, int, error) {
+		tok, rem, pos := "", in, offset
+		for _, parser := range parsers {
+			tmpTok, tmpRem, newPos, err := parser(rem, pos)
+			if err != nil {
+				return "", in, offset, err
+			}
+			tok += tmpTok
+			rem = tmpRem
+			pos = newPos
+		}
+		return tok, rem, pos, nil
+	}
+}
+
+// OrPos is the TextParserPos equivalent of Or: the parsers are tried in
+// order and the first to succeed wins. If every parser fails, the
+// expected-sets of whichever failures got furthest into the input
+// (the longest-match rule) are merged into the returned error.
+func OrPos(parsers ...TextParserPos) TextParserPos {
+	return func(in string, offset int) (string, string, int, error) {
+		var errs []*ParseError
+		for _, parser := range parsers {
+			tok, rem, newOffset, err := parser(in, offset)
+			if err == nil {
+				return tok, rem, newOffset, nil
+			}
+			if pe, ok := err.(*ParseError); ok {
+				errs = append(errs, pe)
+			}
+		}
+		return "", in, offset, mergeExpected(errs, offset, in)
+	}
+}
+
+// OptionalPos is the TextParserPos equivalent of Optional.
+func OptionalPos(parser TextParserPos) TextParserPos {
+	return func(in string, offset int) (string, string, int, error) {
+		tok, rem, newOffset, err := parser(in, offset)
+		if err != nil {
+			return "", in, offset, nil
+		}
+		return tok, rem, newOffset, nil
+	}
+}
+
+// RepeatPos is the TextParserPos equivalent of Repeat.
+func RepeatPos(parser TextParserPos) TextParserPos {
+	return func(in string, offset int) (string, string, int, error) {
+		tok, rem, pos, err := parser(in, offset)
+		if err != nil {
+			return "", in, offset, err
+		}
+
+		for {
+			t, r, newPos, err := parser(rem, pos)
+			if err != nil {
+				return tok, rem, pos, nil
+			}
+			tok += t
+			rem = r
+			pos = newPos
+		}
+	}
+}
+
+// ExpectUntilPos is the TextParserPos equivalent of ExpectUntil.
+func ExpectUntilPos(parser TextParserPos) TextParserPos {
+	return func(in string, offset int) (string, string, int, error) {
+		tok, rem, pos := "", in, offset
+		for {
+			_, _, _, err := parser(rem, pos)
+			if err == nil {
+				return tok, rem, pos, nil
+			}
+			tmpTok, tmpRem, err1 := ExpectAnyRune(rem)
+			if err1 == ErrNoInput {
+				return "", in, offset, newParseError(pos, "EOF")
+			}
+			tok += tmpTok
+			pos += len(tmpTok)
+			rem = tmpRem
+		}
+	}
+}
+
+// ExpectThroughPos is the TextParserPos equivalent of ExpectThrough.
+func ExpectThroughPos(parser TextParserPos) TextParserPos {
+	return func(in string, offset int) (string, string, int, error) {
+		tok, rem, pos := "", in, offset
+		for {
+			tmpTok, tmpRem, newPos, err := parser(rem, pos)
+			if err == nil {
+				tok += tmpTok
+				return tok, tmpRem, newPos, nil
+			}
+			tmpTok, tmpRem, err1 := ExpectAnyRune(rem)
+			if err1 == ErrNoInput {
+				return "", in, offset, newParseError(pos, "EOF")
+			}
+			tok += tmpTok
+			pos += len(tmpTok)
+			rem = tmpRem
+		}
+	}
+}
+
+// ExpectBytePos is the TextParserPos equivalent of ExpectByte.
+func ExpectBytePos(b byte) TextParserPos {
+	return func(in string, offset int) (string, string, int, error) {
+		if len(in) == 0 {
+			return "", "", offset, newParseError(offset, "EOF", string(b))
+		}
+		if in[0] != b {
+			return "", in, offset, newParseError(offset, string(in[0]), string(b))
+		}
+		return in[:1], in[1:], offset + 1, nil
+	}
+}
+
+// ExpectRunePos is the TextParserPos equivalent of ExpectRune.
+func ExpectRunePos(r rune) TextParserPos {
+	return func(in string, offset int) (string, string, int, error) {
+		if len(in) == 0 {
+			return "", "", offset, newParseError(offset, "EOF", string(r))
+		}
+		got, s := utf8.DecodeRuneInString(in)
+		if r != got {
+			return "", in, offset, newParseError(offset, string(got), string(r))
+		}
+		return in[:s], in[s:], offset + s, nil
+	}
+}
+
+// ExpectStringPos is the TextParserPos equivalent of ExpectString.
+func ExpectStringPos(s string) TextParserPos {
+	return func(in string, offset int) (string, string, int, error) {
+		if len(s) > len(in) {
+			return "", in, offset, newParseError(offset, in, s)
+		}
+		if s != in[:len(s)] {
+			return "", in, offset, newParseError(offset, in[:len(s)], s)
+		}
+		return in[:len(s)], in[len(s):], offset + len(s), nil
+	}
+}
+
+// ExpectCaseInsensitiveStringPos is the TextParserPos equivalent of
+// ExpectCaseInsensitiveString.
+func ExpectCaseInsensitiveStringPos(s string) TextParserPos {
+	return func(in string, offset int) (string, string, int, error) {
+		if len(s) > len(in) {
+			return "", in, offset, newParseError(offset, in, s)
+		}
+		if strings.ToUpper(s) != strings.ToUpper(in[:len(s)]) {
+			return "", in, offset, newParseError(offset, in[:len(s)], s)
+		}
+		return in[:len(s)], in[len(s):], offset + len(s), nil
+	}
+}
+
+// ExpectRuneFromPos is the TextParserPos equivalent of ExpectRuneFrom.
+func ExpectRuneFromPos(s string) TextParserPos {
+	m := make(map[rune]struct{})
+	for _, r := range s {
+		m[r] = struct{}{}
+	}
+	return func(in string, offset int) (string, string, int, error) {
+		if len(in) == 0 {
+			return "", "", offset, newParseError(offset, "EOF", "rune from "+s)
+		}
+		r, size := utf8.DecodeRuneInString(in)
+		if _, ok := m[r]; !ok {
+			return "", in, offset, newParseError(offset, string(r), "rune from "+s)
+		}
+		return in[:size], in[size:], offset + size, nil
+	}
+}
+
+// ExpectAnyRunePos is the TextParserPos equivalent of ExpectAnyRune.
+func ExpectAnyRunePos(in string, offset int) (string, string, int, error) {
+	if len(in) == 0 {
+		return "", "", offset, newParseError(offset, "EOF", "any rune")
+	}
+	_, size := utf8.DecodeRuneInString(in)
+	return in[:size], in[size:], offset + size, nil
+}
+
+// ExpectEOIPos is the TextParserPos equivalent of ExpectEOI.
+func ExpectEOIPos(in string, offset int) (string, string, int, error) {
+	if in != "" {
+		r, _ := utf8.DecodeRuneInString(in)
+		return "", in, offset, newParseError(offset, string(r), "EOF")
+	}
+	return "", "", offset, nil
+}
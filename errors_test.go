@@ -0,0 +1,159 @@
+package goparsec
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLineCol(t *testing.T) {
+	in := "abc\ndef\nghi"
+	tests := []struct {
+		offset   int
+		wantLine int
+		wantCol  int
+	}{
+		{0, 1, 1},
+		{2, 1, 3},
+		{4, 2, 1},
+		{6, 2, 3},
+		{8, 3, 1},
+	}
+	for _, tt := range tests {
+		line, col := lineCol(in, tt.offset)
+		if line != tt.wantLine || col != tt.wantCol {
+			t.Errorf("lineCol(%d) = (%d, %d), want (%d, %d)", tt.offset, line, col, tt.wantLine, tt.wantCol)
+		}
+	}
+}
+
+func TestExpectBytePosError(t *testing.T) {
+	in := "ab\ncd"
+	p := AndPos(FromTextParser(ExpectString("ab\ncd")), ExpectBytePos('!'))
+	_, _, _, err := p(in, 0)
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	pe, ok := err.(*ParseError)
+	if !ok {
+		t.Fatalf("expected a *ParseError, got %T", err)
+	}
+	pe.Resolve(in)
+	if pe.Line != 2 || pe.Col != 3 {
+		t.Errorf("got line %d col %d, want line 2 col 3", pe.Line, pe.Col)
+	}
+}
+
+func TestOrPosMergesExpected(t *testing.T) {
+	p := OrPos(
+		AndPos(ExpectBytePos('a'), ExpectBytePos('1')),
+		AndPos(ExpectBytePos('a'), ExpectBytePos('2')),
+	)
+	_, _, _, err := p("ax", 0)
+	pe, ok := err.(*ParseError)
+	if !ok {
+		t.Fatalf("expected a *ParseError, got %T", err)
+	}
+	if len(pe.Expected) != 2 || pe.Expected[0] != "1" || pe.Expected[1] != "2" {
+		t.Errorf("Expected = %v, want [1 2]", pe.Expected)
+	}
+}
+
+func TestLabel(t *testing.T) {
+	digit := Label("digit", FromTextParser(ExpectDigit))
+	_, _, _, err := digit("x", 0)
+	pe, ok := err.(*ParseError)
+	if !ok {
+		t.Fatalf("expected a *ParseError, got %T", err)
+	}
+	if pe.Error() != "line 0 col 0: expected digit, got x" {
+		t.Errorf("unexpected message: %s", pe.Error())
+	}
+}
+
+func TestToTextParserResolvesPosition(t *testing.T) {
+	in := strings.Repeat("a", 3) + "\n!"
+	p := ToTextParser(RepeatPos(ExpectBytePos('a')))
+	_, rem, err := p(in)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rem != "\n!" {
+		t.Errorf("got remaining %q, want %q", rem, "\n!")
+	}
+
+	_, _, err = ToTextParser(AndPos(RepeatPos(ExpectBytePos('a')), ExpectBytePos('\n'), ExpectBytePos('?')))(in)
+	pe, ok := err.(*ParseError)
+	if !ok {
+		t.Fatalf("expected a *ParseError, got %T", err)
+	}
+	if pe.Line != 2 || pe.Col != 1 {
+		t.Errorf("got line %d col %d, want line 2 col 1", pe.Line, pe.Col)
+	}
+}
+
+func TestExpectCaseInsensitiveStringPos(t *testing.T) {
+	tok, rem, newOffset, err := ExpectCaseInsensitiveStringPos("HELLO")("Hello World", 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tok != "Hello" || rem != " World" || newOffset != 5 {
+		t.Errorf("got (%q, %q, %d), want (%q, %q, 5)", tok, rem, newOffset, "Hello", " World")
+	}
+
+	_, _, _, err = ExpectCaseInsensitiveStringPos("HELLO")("Goodbye", 0)
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+}
+
+func TestExpectRuneFromPos(t *testing.T) {
+	p := ExpectRuneFromPos("abc")
+	tok, rem, newOffset, err := p("bX", 0)
+	if err != nil || tok != "b" || rem != "X" || newOffset != 1 {
+		t.Errorf("got (%q, %q, %d, %v)", tok, rem, newOffset, err)
+	}
+
+	_, _, _, err = p("X", 0)
+	pe, ok := err.(*ParseError)
+	if !ok {
+		t.Fatalf("expected a *ParseError, got %T", err)
+	}
+	if len(pe.Expected) != 1 || pe.Expected[0] != "rune from abc" {
+		t.Errorf("Expected = %v, want [rune from abc]", pe.Expected)
+	}
+}
+
+func TestExpectAnyRunePos(t *testing.T) {
+	tok, rem, newOffset, err := ExpectAnyRunePos("日b", 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tok != "日" || rem != "b" || newOffset != len("日") {
+		t.Errorf("got (%q, %q, %d)", tok, rem, newOffset)
+	}
+
+	_, _, _, err = ExpectAnyRunePos("", 0)
+	if err == nil {
+		t.Fatalf("expected an error on empty input")
+	}
+}
+
+func TestExpectEOIPos(t *testing.T) {
+	_, _, _, err := ExpectEOIPos("", 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, _, _, err = ExpectEOIPos("x", 0)
+	if err == nil {
+		t.Fatalf("expected an error when input remains")
+	}
+}
+
+func BenchmarkAndPos_NoError(b *testing.B) {
+	p := AndPos(ExpectBytePos('a'), ExpectBytePos('b'), ExpectBytePos('c'))
+	for n := 0; n < b.N; n++ {
+		_, _, _, err := p("abc", 0)
+		_ = err
+	}
+}
@@ -0,0 +1,409 @@
+package goparsec
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// Input is a cursor over parser input that need not be fully resident
+// in memory, unlike the plain string TextParser is built on.
+type Input interface {
+	// Peek returns up to n bytes starting at the current position
+	// without advancing it. A short (or empty) result with a nil
+	// error means the underlying source ran out before n bytes were
+	// available.
+	Peek(n int) ([]byte, error)
+	// Advance moves the current position forward by n bytes, which
+	// must already have been returned by a prior Peek.
+	Advance(n int)
+	// Mark returns a token identifying the current position, for a
+	// later Restore.
+	Mark() int64
+	// Restore rewinds to a position previously returned by Mark.
+	Restore(mark int64)
+	// Unmark tells the Input that a previously returned Mark will never
+	// be Restore'd to again, so any data kept around only to satisfy it
+	// can be freed. Every Mark must eventually be Unmark'd exactly once.
+	Unmark(mark int64)
+}
+
+// Cursor is an Input backed by an io.Reader. It buffers bytes lazily,
+// chunkSize at a time, and discards buffered data behind the oldest
+// outstanding Mark, so memory use is bounded by how far a parse
+// backtracks rather than by the size of the whole stream.
+type Cursor struct {
+	r         io.Reader
+	chunkSize int
+	buf       []byte
+	base      int64 // stream offset of buf[0]
+	pos       int64 // current stream offset
+	eof       bool
+	marks     []int64 // outstanding marks, oldest first
+}
+
+// NewCursor returns a Cursor reading from r in chunks of chunkSize
+// bytes. A chunkSize <= 0 uses a 4KB default.
+func NewCursor(r io.Reader, chunkSize int) *Cursor {
+	if chunkSize <= 0 {
+		chunkSize = 4096
+	}
+	return &Cursor{r: r, chunkSize: chunkSize}
+}
+
+func (c *Cursor) fill(upto int64) error {
+	for c.base+int64(len(c.buf)) < upto && !c.eof {
+		chunk := make([]byte, c.chunkSize)
+		n, err := c.r.Read(chunk)
+		if n > 0 {
+			c.buf = append(c.buf, chunk[:n]...)
+		}
+		if err != nil {
+			if err == io.EOF {
+				c.eof = true
+				break
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// Peek implements Input.
+func (c *Cursor) Peek(n int) ([]byte, error) {
+	if err := c.fill(c.pos + int64(n)); err != nil {
+		return nil, err
+	}
+	start := c.pos - c.base
+	end := start + int64(n)
+	if end > int64(len(c.buf)) {
+		end = int64(len(c.buf))
+	}
+	return c.buf[start:end], nil
+}
+
+// Advance implements Input.
+func (c *Cursor) Advance(n int) {
+	c.pos += int64(n)
+}
+
+// Mark implements Input.
+func (c *Cursor) Mark() int64 {
+	c.marks = append(c.marks, c.pos)
+	return c.pos
+}
+
+// Restore implements Input. The mark must still be covered by buffered
+// data, i.e. not yet discarded as a result of a prior Unmark.
+func (c *Cursor) Restore(mark int64) {
+	c.pos = mark
+}
+
+// Unmark implements Input, discarding buffered data behind the oldest
+// mark still outstanding after this one is removed.
+func (c *Cursor) Unmark(mark int64) {
+	if n := len(c.marks); n > 0 && c.marks[n-1] == mark {
+		c.marks = c.marks[:n-1]
+	}
+	floor := c.pos
+	if len(c.marks) > 0 {
+		floor = c.marks[0]
+	}
+	c.Discard(floor)
+}
+
+// Discard forgets buffered bytes before pos, freeing the memory they
+// occupied. Any outstanding Mark before pos becomes invalid; this
+// is called automatically from Unmark and only needs to be called
+// directly by code that manages its own marks outside the Input
+// interface.
+func (c *Cursor) Discard(pos int64) {
+	if pos <= c.base {
+		return
+	}
+	if pos > c.pos {
+		pos = c.pos
+	}
+	c.buf = c.buf[pos-c.base:]
+	c.base = pos
+}
+
+// stringInput adapts a string into an Input so StreamParser values can
+// run against in-memory input without going through an io.Reader. The
+// whole string is already resident in memory, so Unmark has nothing
+// useful to discard.
+type stringInput struct {
+	s   string
+	pos int
+}
+
+// FromString adapts a string into an Input for use with StreamParser.
+func FromString(s string) Input {
+	return &stringInput{s: s}
+}
+
+func (s *stringInput) Peek(n int) ([]byte, error) {
+	end := s.pos + n
+	if end > len(s.s) {
+		end = len(s.s)
+	}
+	if end < s.pos {
+		end = s.pos
+	}
+	return []byte(s.s[s.pos:end]), nil
+}
+
+func (s *stringInput) Advance(n int) {
+	s.pos += n
+}
+
+func (s *stringInput) Mark() int64 {
+	return int64(s.pos)
+}
+
+func (s *stringInput) Restore(mark int64) {
+	s.pos = int(mark)
+}
+
+func (s *stringInput) Unmark(mark int64) {}
+
+// StreamParser is the Input-based counterpart to TextParser: it reads
+// from an Input rather than requiring the whole input up front as a
+// string, so Repeat and ExpectUntil can process sources larger than
+// memory.
+type StreamParser func(Input) (tok string, err error)
+
+func checkInputSizeStream(p StreamParser) StreamParser {
+	return func(in Input) (string, error) {
+		b, err := in.Peek(1)
+		if err != nil {
+			return "", err
+		}
+		if len(b) == 0 {
+			return "", ErrNoInput
+		}
+		return p(in)
+	}
+}
+
+// ExpectByteStream is the StreamParser equivalent of ExpectByte.
+func ExpectByteStream(b byte) StreamParser {
+	return checkInputSizeStream(func(in Input) (string, error) {
+		buf, err := in.Peek(1)
+		if err != nil {
+			return "", err
+		}
+		if buf[0] != b {
+			return "", expectByteError(b, buf[0])
+		}
+		in.Advance(1)
+		return string(buf[:1]), nil
+	})
+}
+
+// ExpectRuneStream is the StreamParser equivalent of ExpectRune.
+func ExpectRuneStream(r rune) StreamParser {
+	return checkInputSizeStream(func(in Input) (string, error) {
+		buf, err := in.Peek(utf8.UTFMax)
+		if err != nil {
+			return "", err
+		}
+		got, size := utf8.DecodeRune(buf)
+		if r != got {
+			return "", expectRuneError(r, got)
+		}
+		in.Advance(size)
+		return string(buf[:size]), nil
+	})
+}
+
+// ExpectStringStream is the StreamParser equivalent of ExpectString.
+func ExpectStringStream(s string) StreamParser {
+	return checkInputSizeStream(func(in Input) (string, error) {
+		buf, err := in.Peek(len(s))
+		if err != nil {
+			return "", err
+		}
+		if len(buf) < len(s) || s != string(buf) {
+			return "", expectStringError(s, string(buf))
+		}
+		in.Advance(len(s))
+		return s, nil
+	})
+}
+
+// ExpectRuneFromStream is the StreamParser equivalent of ExpectRuneFrom.
+func ExpectRuneFromStream(s string) StreamParser {
+	m := make(map[rune]struct{})
+	for _, r := range s {
+		m[r] = struct{}{}
+	}
+	return checkInputSizeStream(func(in Input) (string, error) {
+		buf, err := in.Peek(utf8.UTFMax)
+		if err != nil {
+			return "", err
+		}
+		r, size := utf8.DecodeRune(buf)
+		if _, ok := m[r]; !ok {
+			return "", expectRuneFromError(s, r)
+		}
+		in.Advance(size)
+		return string(buf[:size]), nil
+	})
+}
+
+// ExpectAnyRuneStream is the StreamParser equivalent of ExpectAnyRune.
+var ExpectAnyRuneStream StreamParser = checkInputSizeStream(func(in Input) (string, error) {
+	buf, err := in.Peek(utf8.UTFMax)
+	if err != nil {
+		return "", err
+	}
+	_, size := utf8.DecodeRune(buf)
+	in.Advance(size)
+	return string(buf[:size]), nil
+})
+
+// ExpectEOIStream is the StreamParser equivalent of ExpectEOI.
+var ExpectEOIStream StreamParser = func(in Input) (string, error) {
+	b, err := in.Peek(1)
+	if err != nil {
+		return "", err
+	}
+	if len(b) != 0 {
+		return "", expectEOIError()
+	}
+	return "", nil
+}
+
+// AndStream is the StreamParser equivalent of And. If any parser
+// fails, the input is restored to where And started.
+func AndStream(parsers ...StreamParser) StreamParser {
+	return func(in Input) (string, error) {
+		mark := in.Mark()
+		var sb strings.Builder
+		for _, p := range parsers {
+			t, err := p(in)
+			if err != nil {
+				in.Restore(mark)
+				in.Unmark(mark)
+				return "", err
+			}
+			sb.WriteString(t)
+		}
+		in.Unmark(mark)
+		return sb.String(), nil
+	}
+}
+
+// OrStream is the StreamParser equivalent of Or. The input is
+// snapshotted before each alternative is tried and restored if it
+// fails, so a partially-consumed alternative never leaks into the
+// next one.
+func OrStream(parsers ...StreamParser) StreamParser {
+	return func(in Input) (string, error) {
+		mark := in.Mark()
+		for _, p := range parsers {
+			tok, err := p(in)
+			if err == nil {
+				in.Unmark(mark)
+				return tok, nil
+			}
+			in.Restore(mark)
+		}
+		in.Unmark(mark)
+		return "", fmt.Errorf("No match")
+	}
+}
+
+// OptionalStream is the StreamParser equivalent of Optional.
+func OptionalStream(p StreamParser) StreamParser {
+	return func(in Input) (string, error) {
+		mark := in.Mark()
+		tok, err := p(in)
+		if err != nil {
+			in.Restore(mark)
+			in.Unmark(mark)
+			return "", nil
+		}
+		in.Unmark(mark)
+		return tok, nil
+	}
+}
+
+// RepeatStream is the StreamParser equivalent of Repeat. Each
+// repetition's mark is Unmark'd as soon as it resolves, so a Cursor
+// never has to retain more than the unconsumed tail of the stream.
+func RepeatStream(p StreamParser) StreamParser {
+	return func(in Input) (string, error) {
+		var sb strings.Builder
+		t, err := p(in)
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString(t)
+		for {
+			mark := in.Mark()
+			t, err := p(in)
+			if err != nil {
+				in.Restore(mark)
+				in.Unmark(mark)
+				return sb.String(), nil
+			}
+			in.Unmark(mark)
+			sb.WriteString(t)
+		}
+	}
+}
+
+// ExpectUntilStream is the StreamParser equivalent of ExpectUntil.
+func ExpectUntilStream(p StreamParser) StreamParser {
+	return func(in Input) (string, error) {
+		var sb strings.Builder
+		for {
+			mark := in.Mark()
+			_, err := p(in)
+			in.Restore(mark)
+			in.Unmark(mark)
+			if err == nil {
+				return sb.String(), nil
+			}
+			b, perr := in.Peek(1)
+			if perr != nil {
+				return "", perr
+			}
+			if len(b) == 0 {
+				return "", ErrNoInput
+			}
+			in.Advance(1)
+			sb.WriteByte(b[0])
+		}
+	}
+}
+
+// ExpectThroughStream is the StreamParser equivalent of ExpectThrough.
+func ExpectThroughStream(p StreamParser) StreamParser {
+	return func(in Input) (string, error) {
+		var sb strings.Builder
+		for {
+			mark := in.Mark()
+			t, err := p(in)
+			if err == nil {
+				in.Unmark(mark)
+				sb.WriteString(t)
+				return sb.String(), nil
+			}
+			in.Restore(mark)
+			in.Unmark(mark)
+			b, perr := in.Peek(1)
+			if perr != nil {
+				return "", perr
+			}
+			if len(b) == 0 {
+				return "", ErrNoInput
+			}
+			in.Advance(1)
+			sb.WriteByte(b[0])
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package goparsec
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCursorPeekAdvance(t *testing.T) {
+	c := NewCursor(strings.NewReader("hello world"), 4)
+
+	b, err := c.Peek(5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(b) != "hello" {
+		t.Fatalf("Peek(5) = %q, want %q", b, "hello")
+	}
+	c.Advance(5)
+
+	b, err = c.Peek(6)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(b) != " world" {
+		t.Fatalf("Peek(6) = %q, want %q", b, " world")
+	}
+}
+
+func TestCursorMarkRestore(t *testing.T) {
+	c := NewCursor(strings.NewReader("abcdef"), 2)
+	c.Advance(3)
+	mark := c.Mark()
+	c.Advance(2)
+	c.Restore(mark)
+
+	b, _ := c.Peek(3)
+	if string(b) != "def" {
+		t.Fatalf("after Restore, Peek(3) = %q, want %q", b, "def")
+	}
+}
+
+func TestCursorShortReadAtEOF(t *testing.T) {
+	c := NewCursor(strings.NewReader("ab"), 4)
+	b, err := c.Peek(10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(b) != "ab" {
+		t.Fatalf("Peek(10) at EOF = %q, want %q", b, "ab")
+	}
+}
+
+func TestAndStreamRestoresOnFailure(t *testing.T) {
+	in := FromString("ab")
+	p := AndStream(ExpectByteStream('a'), ExpectByteStream('z'))
+	_, err := p(in)
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	b, _ := in.Peek(2)
+	if string(b) != "ab" {
+		t.Fatalf("input was not restored: Peek(2) = %q, want %q", b, "ab")
+	}
+}
+
+func TestOrStreamRestoresBetweenAlternatives(t *testing.T) {
+	in := FromString("cd")
+	p := OrStream(ExpectStringStream("ab"), ExpectStringStream("cd"))
+	tok, err := p(in)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tok != "cd" {
+		t.Fatalf("got token %q, want %q", tok, "cd")
+	}
+}
+
+func TestRepeatStreamOverChunkBoundaries(t *testing.T) {
+	in := strings.Repeat("a", 10000) + "b"
+	c := NewCursor(strings.NewReader(in), 256)
+	p := RepeatStream(ExpectByteStream('a'))
+
+	tok, err := p(c)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(tok) != 10000 {
+		t.Fatalf("got %d repetitions, want 10000", len(tok))
+	}
+	b, _ := c.Peek(1)
+	if string(b) != "b" {
+		t.Fatalf("Peek(1) after Repeat = %q, want %q", b, "b")
+	}
+}
+
+func TestRepeatStreamDiscardsBufferedBytes(t *testing.T) {
+	in := strings.Repeat("a", 1_000_000) + "b"
+	c := NewCursor(strings.NewReader(in), 4096)
+	p := RepeatStream(ExpectByteStream('a'))
+
+	if _, err := p(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(c.buf) > 2*c.chunkSize {
+		t.Errorf("Cursor.buf holds %d bytes after a 1,000,000-byte repeat, want it bounded by a few chunks", len(c.buf))
+	}
+}
+
+func BenchmarkRepeatStream_100MB(b *testing.B) {
+	data := strings.Repeat("a", 100*1024*1024) + "!"
+	for n := 0; n < b.N; n++ {
+		c := NewCursor(strings.NewReader(data), 64*1024)
+		_, err := RepeatStream(ExpectByteStream('a'))(c)
+		if err != nil {
+			b.Fatalf("unexpected error: %v", err)
+		}
+	}
+}
+
+func BenchmarkRepeat_100MB_String(b *testing.B) {
+	data := strings.Repeat("a", 100*1024*1024) + "!"
+	for n := 0; n < b.N; n++ {
+		_, _, err := Repeat(ExpectByte('a'))(data)
+		if err != nil {
+			b.Fatalf("unexpected error: %v", err)
+		}
+	}
+}
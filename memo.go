@@ -0,0 +1,68 @@
+package goparsec
+
+import "fmt"
+
+// Memo wraps a parser so that repeated calls on the same remaining input
+// reuse the first result instead of re-running the parser. The cache is
+// unbounded for the life of the returned TextParser, so build a fresh
+// one per parse if that matters.
+func Memo(p TextParser) TextParser {
+	type result struct {
+		tok, rem string
+		err      error
+	}
+	cache := make(map[string]result)
+
+	return func(in string) (string, string, error) {
+		if r, ok := cache[in]; ok {
+			return r.tok, r.rem, r.err
+		}
+		tok, rem, err := p(in)
+		cache[in] = result{tok, rem, err}
+		return tok, rem, err
+	}
+}
+
+// LeftRecursive lets a rule be written in its natural, directly
+// left-recursive form (expr = expr '+' term | term) without p recursing
+// forever, using the seed-growing packrat algorithm. The final result is
+// cached for that position, same as Memo.
+func LeftRecursive(name string, p TextParser) TextParser {
+	type seed struct {
+		tok, rem string
+		err      error
+	}
+	active := make(map[string]*seed) // growth in progress, keyed by position
+	done := make(map[string]seed)    // final results, cached for the life of self
+
+	var self TextParser
+	self = func(in string) (string, string, error) {
+		if r, ok := done[in]; ok {
+			return r.tok, r.rem, r.err
+		}
+		if s, ok := active[in]; ok {
+			return s.tok, s.rem, s.err
+		}
+
+		s := &seed{rem: in, err: fmt.Errorf("%s: no match", name)}
+		active[in] = s
+		defer delete(active, in)
+
+		consumed := -1 // sentinel so a zero-length first match still counts as growth
+		for {
+			tok, rem, err := p(in)
+			if err != nil {
+				break
+			}
+			n := len(in) - len(rem)
+			if n <= consumed {
+				break
+			}
+			consumed = n
+			s.tok, s.rem, s.err = tok, rem, nil
+		}
+		done[in] = *s
+		return s.tok, s.rem, s.err
+	}
+	return self
+}
@@ -0,0 +1,94 @@
+package goparsec
+
+import "testing"
+
+func TestMemoAvoidsReparsing(t *testing.T) {
+	calls := 0
+	counting := func(in string) (string, string, error) {
+		calls++
+		return ExpectByte('a')(in)
+	}
+	p := Memo(counting)
+
+	for i := 0; i < 3; i++ {
+		tok, rem, err := p("abc")
+		if err != nil || tok != "a" || rem != "bc" {
+			t.Fatalf("got (%q, %q, %v)", tok, rem, err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("underlying parser was called %d times, want 1", calls)
+	}
+
+	// A different position is not served from the same cache entry.
+	if _, _, err := p("xyz"); err == nil {
+		t.Errorf("expected an error for a non-matching position")
+	}
+	if calls != 2 {
+		t.Errorf("underlying parser was called %d times, want 2", calls)
+	}
+}
+
+func TestLeftRecursiveArithmetic(t *testing.T) {
+	term := Repeat(ExpectDigit)
+
+	var expr TextParser
+	expr = LeftRecursive("expr", func(in string) (string, string, error) {
+		return Or(
+			And(expr, ExpectByte('+'), term),
+			term,
+		)(in)
+	})
+
+	tests := []struct {
+		in  string
+		tok string
+		rem string
+	}{
+		{"1", "1", ""},
+		{"1+2", "1+2", ""},
+		{"1+2+3", "1+2+3", ""},
+		{"1+2+3;", "1+2+3", ";"},
+	}
+	for _, tt := range tests {
+		tok, rem, err := expr(tt.in)
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %v", tt.in, err)
+		}
+		if tok != tt.tok || rem != tt.rem {
+			t.Errorf("%q: got (%q, %q), want (%q, %q)", tt.in, tok, rem, tt.tok, tt.rem)
+		}
+	}
+
+	if _, _, err := expr(";"); err == nil {
+		t.Errorf("expected an error when no term is present")
+	}
+}
+
+func TestLeftRecursiveCachesFinalResult(t *testing.T) {
+	calls := 0
+	term := Repeat(ExpectDigit)
+
+	var expr TextParser
+	expr = LeftRecursive("expr", func(in string) (string, string, error) {
+		calls++
+		return Or(
+			And(expr, ExpectByte('+'), term),
+			term,
+		)(in)
+	})
+
+	tok, rem, err := expr("1+2")
+	if err != nil || tok != "1+2" || rem != "" {
+		t.Fatalf("got (%q, %q, %v)", tok, rem, err)
+	}
+	afterFirst := calls
+
+	tok, rem, err = expr("1+2")
+	if err != nil || tok != "1+2" || rem != "" {
+		t.Fatalf("got (%q, %q, %v)", tok, rem, err)
+	}
+	if calls != afterFirst {
+		t.Errorf("p was called again on a repeat parse at the same position: %d calls before, %d after (result should be cached)", afterFirst, calls)
+	}
+}
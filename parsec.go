@@ -250,15 +250,6 @@ func expectEOIError() error {
 }
 
 var (
-	// ExpectDigit expects a digit 0-9
-	ExpectDigit = ExpectRuneFrom("1234567890")
-
-	// ExpectLetter expects a character from a-zA-Z
-	ExpectLetter = ExpectRuneFrom("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ")
-
-	// ExpectWhiteSpace expects a space, tab, carriage return, or newline
-	ExpectWhiteSpace = ExpectRuneFrom(" \t\r\n")
-
 	// ExpectUnixNewLine expects an \n
 	ExpectUnixNewLine = ExpectRune('\n')
 
@@ -0,0 +1,256 @@
+// Package pegn lets a grammar be described declaratively with Go slice
+// and struct literals, and compiled down to goparsec.TextParser values,
+// instead of being wired up by hand with And/Or/Repeat calls.
+package pegn
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	goparsec "github.com/tbshill/goparsec"
+)
+
+// Seq is an ordered sequence of grammar nodes that must all match in
+// order. The resulting token is the concatenation of their output.
+type Seq []any
+
+// Any is an ordered choice of grammar nodes: the first one that matches
+// wins.
+type Any []any
+
+// Opt makes a grammar node optional.
+type Opt struct{ X any }
+
+// Not is a negative lookahead: it matches (consuming nothing) only when
+// X does not match at the current position.
+type Not struct{ X any }
+
+// To consumes runes up to, but not including, a match of X.
+type To struct{ X any }
+
+// Thru consumes runes up to and including a match of X.
+type Thru struct{ X any }
+
+// N bounds the number of repetitions of X to the range [Min, Max]. A
+// Max of 0 or less means unbounded.
+type N struct {
+	Min, Max int
+	X        any
+}
+
+// Rng matches a single rune in the inclusive range [Lo, Hi].
+type Rng struct{ Lo, Hi rune }
+
+// In matches any single rune contained in the string.
+type In string
+
+// Ref is a named reference to another rule in a Grammar, resolved by
+// Compile. This is how recursive and forward references are expressed.
+type Ref string
+
+// Grammar maps rule names to grammar nodes. A node may reference other
+// rules, including itself, via Ref.
+type Grammar map[string]any
+
+// Compile walks the grammar starting at the rule named start and
+// produces a single TextParser for it. Rules are compiled once and
+// memoized by name, so a rule referenced from multiple places (or
+// recursively) is only ever built once. Immediate left recursion is
+// rejected at compile time, since it would otherwise overflow the
+// stack the first time the rule is used. Only the first element of a
+// Seq is checked, so a Ref that follows an element able to match empty
+// input is not detected.
+func Compile(g Grammar, start string) (goparsec.TextParser, error) {
+	c := &compiler{g: g, cache: map[string]*goparsec.TextParser{}}
+	p, err := c.rule(start)
+	if err != nil {
+		return nil, err
+	}
+	return *p, nil
+}
+
+type compiler struct {
+	g     Grammar
+	cache map[string]*goparsec.TextParser
+}
+
+func (c *compiler) rule(name string) (*goparsec.TextParser, error) {
+	if p, ok := c.cache[name]; ok {
+		return p, nil
+	}
+	node, ok := c.g[name]
+	if !ok {
+		return nil, fmt.Errorf("pegn: rule %q is not defined", name)
+	}
+	if startsWithRef(node, name) {
+		return nil, fmt.Errorf("pegn: rule %q is immediately left-recursive", name)
+	}
+
+	// Reserve a slot before compiling the body, so a Ref back to this
+	// rule (direct or indirect recursion) resolves to the same parser
+	// value once it's finished compiling.
+	var parser goparsec.TextParser
+	wrapped := goparsec.TextParser(func(in string) (string, string, error) { return parser(in) })
+	c.cache[name] = &wrapped
+
+	p, err := c.compile(node)
+	if err != nil {
+		delete(c.cache, name)
+		return nil, err
+	}
+	parser = p
+	return &wrapped, nil
+}
+
+func (c *compiler) compile(node any) (goparsec.TextParser, error) {
+	switch n := node.(type) {
+	case Seq:
+		parsers, err := c.compileAll(n)
+		if err != nil {
+			return nil, err
+		}
+		return goparsec.And(parsers...), nil
+	case Any:
+		parsers, err := c.compileAll(n)
+		if err != nil {
+			return nil, err
+		}
+		return goparsec.Or(parsers...), nil
+	case Opt:
+		p, err := c.compile(n.X)
+		if err != nil {
+			return nil, err
+		}
+		return goparsec.Optional(p), nil
+	case Not:
+		p, err := c.compile(n.X)
+		if err != nil {
+			return nil, err
+		}
+		return not(p), nil
+	case To:
+		p, err := c.compile(n.X)
+		if err != nil {
+			return nil, err
+		}
+		return goparsec.ExpectUntil(p), nil
+	case Thru:
+		p, err := c.compile(n.X)
+		if err != nil {
+			return nil, err
+		}
+		return goparsec.ExpectThrough(p), nil
+	case N:
+		p, err := c.compile(n.X)
+		if err != nil {
+			return nil, err
+		}
+		return bounded(n.Min, n.Max, p), nil
+	case Rng:
+		return runeRange(n.Lo, n.Hi), nil
+	case In:
+		return goparsec.ExpectRuneFrom(string(n)), nil
+	case Ref:
+		p, err := c.rule(string(n))
+		if err != nil {
+			return nil, err
+		}
+		return func(in string) (string, string, error) { return (*p)(in) }, nil
+	case string:
+		return goparsec.ExpectString(n), nil
+	case rune:
+		return goparsec.ExpectRune(n), nil
+	case goparsec.TextParser:
+		return n, nil
+	default:
+		return nil, fmt.Errorf("pegn: unsupported grammar node of type %T", node)
+	}
+}
+
+func (c *compiler) compileAll(nodes []any) ([]goparsec.TextParser, error) {
+	parsers := make([]goparsec.TextParser, 0, len(nodes))
+	for _, node := range nodes {
+		p, err := c.compile(node)
+		if err != nil {
+			return nil, err
+		}
+		parsers = append(parsers, p)
+	}
+	return parsers, nil
+}
+
+// startsWithRef reports whether node's leftmost element, before any
+// input is consumed, could be a reference back to name. Only the first
+// element of a Seq is followed.
+func startsWithRef(node any, name string) bool {
+	switch n := node.(type) {
+	case Ref:
+		return string(n) == name
+	case Opt:
+		return startsWithRef(n.X, name)
+	case Not:
+		return startsWithRef(n.X, name)
+	case To:
+		return startsWithRef(n.X, name)
+	case Thru:
+		return startsWithRef(n.X, name)
+	case N:
+		return startsWithRef(n.X, name)
+	case Seq:
+		if len(n) == 0 {
+			return false
+		}
+		return startsWithRef(n[0], name)
+	case Any:
+		for _, alt := range n {
+			if startsWithRef(alt, name) {
+				return true
+			}
+		}
+		return false
+	default:
+		return false
+	}
+}
+
+func not(p goparsec.TextParser) goparsec.TextParser {
+	return func(in string) (string, string, error) {
+		if _, _, err := p(in); err == nil {
+			return "", in, fmt.Errorf("pegn: unexpected match")
+		}
+		return "", in, nil
+	}
+}
+
+func bounded(min, max int, p goparsec.TextParser) goparsec.TextParser {
+	return func(in string) (string, string, error) {
+		tok, rem := "", in
+		count := 0
+		for max <= 0 || count < max {
+			t, r, err := p(rem)
+			if err != nil {
+				break
+			}
+			tok += t
+			rem = r
+			count++
+		}
+		if count < min {
+			return "", in, fmt.Errorf("pegn: expected at least %d repetitions, got %d", min, count)
+		}
+		return tok, rem, nil
+	}
+}
+
+func runeRange(lo, hi rune) goparsec.TextParser {
+	return func(in string) (string, string, error) {
+		if len(in) == 0 {
+			return "", "", goparsec.ErrNoInput
+		}
+		r, size := utf8.DecodeRuneInString(in)
+		if r < lo || r > hi {
+			return "", in, fmt.Errorf("pegn: expected rune in range [%c-%c], got %c", lo, hi, r)
+		}
+		return in[:size], in[size:], nil
+	}
+}
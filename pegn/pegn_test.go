@@ -0,0 +1,85 @@
+package pegn
+
+import (
+	"testing"
+
+	goparsec "github.com/tbshill/goparsec"
+)
+
+func TestCompileArithmeticExpression(t *testing.T) {
+	g := Grammar{
+		"expr":   Seq{Ref("term"), N{0, -1, Seq{In("+-"), Ref("term")}}},
+		"term":   Seq{Ref("factor"), N{0, -1, Seq{In("*/"), Ref("factor")}}},
+		"factor": Any{N{1, -1, Rng{'0', '9'}}, Seq{"(", Ref("expr"), ")"}},
+	}
+	p, err := Compile(g, "expr")
+	if err != nil {
+		t.Fatalf("Compile returned an error: %v", err)
+	}
+
+	handwritten := newHandwrittenExpr()
+
+	for _, in := range []string{"1+2*3", "(1+2)*3", "12*34+5"} {
+		gotTok, gotRem, gotErr := p(in)
+		wantTok, wantRem, wantErr := handwritten(in)
+		if (gotErr == nil) != (wantErr == nil) {
+			t.Fatalf("%q: compiled err = %v, handwritten err = %v", in, gotErr, wantErr)
+		}
+		if gotTok != wantTok || gotRem != wantRem {
+			t.Errorf("%q: compiled = (%q, %q), handwritten = (%q, %q)", in, gotTok, gotRem, wantTok, wantRem)
+		}
+	}
+}
+
+// newHandwrittenExpr builds the same arithmetic-expression parser as
+// the grammar in TestCompileArithmeticExpression, but wired up by hand
+// with And/Or/Repeat, to compare against the compiled version. expr is
+// declared before it's assigned so factor's closure can recurse into it
+// without a definition-order cycle.
+func newHandwrittenExpr() goparsec.TextParser {
+	var expr goparsec.TextParser
+
+	digit := goparsec.Repeat(goparsec.ExpectRuneFrom("0123456789"))
+	factor := goparsec.Or(digit, goparsec.And(goparsec.ExpectByte('('), func(in string) (string, string, error) { return expr(in) }, goparsec.ExpectByte(')')))
+	term := goparsec.And(factor, goparsec.Optional(goparsec.Repeat(goparsec.And(goparsec.ExpectRuneFrom("*/"), factor))))
+	expr = goparsec.And(term, goparsec.Optional(goparsec.Repeat(goparsec.And(goparsec.ExpectRuneFrom("+-"), term))))
+
+	return expr
+}
+
+func TestCompileJSONSubset(t *testing.T) {
+	g := Grammar{
+		"digits": N{1, -1, Rng{'0', '9'}},
+		"value":  Ref("digits"),
+		"array":  Seq{"[", Ref("value"), N{0, -1, Seq{",", Ref("value")}}, "]"},
+	}
+	p, err := Compile(g, "array")
+	if err != nil {
+		t.Fatalf("Compile returned an error: %v", err)
+	}
+
+	tok, rem, err := p("[1,23,4]")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tok != "[1,23,4]" || rem != "" {
+		t.Errorf("got (%q, %q), want (%q, %q)", tok, rem, "[1,23,4]", "")
+	}
+}
+
+func TestCompileUnknownRule(t *testing.T) {
+	_, err := Compile(Grammar{"start": Ref("missing")}, "start")
+	if err == nil {
+		t.Fatalf("expected an error for an undefined rule")
+	}
+}
+
+func TestCompileImmediateLeftRecursion(t *testing.T) {
+	g := Grammar{
+		"expr": Any{Seq{Ref("expr"), In("+")}, In("1")},
+	}
+	_, err := Compile(g, "expr")
+	if err == nil {
+		t.Fatalf("expected an error for immediate left recursion")
+	}
+}
@@ -0,0 +1,106 @@
+package goparsec
+
+import "fmt"
+
+// Between requires a parser to succeed at least min and at most max
+// times in a row. A negative max means unbounded; max == 0 means exactly
+// zero repetitions. The token is the concatenation of every repetition's
+// output; if fewer than min repetitions match, the input is left
+// unconsumed and an error is returned.
+func Between(min, max int, p TextParser) TextParser {
+	return func(in string) (string, string, error) {
+		tok, rem := "", in
+		count := 0
+		for max < 0 || count < max {
+			t, r, err := p(rem)
+			if err != nil {
+				break
+			}
+			tok += t
+			rem = r
+			count++
+		}
+		if count < min {
+			return "", in, fmt.Errorf("Expected at least %d repetitions, got %d", min, count)
+		}
+		return tok, rem, nil
+	}
+}
+
+// Times requires a parser to succeed exactly n times in a row.
+func Times(n int, p TextParser) TextParser {
+	return Between(n, n, p)
+}
+
+// AtLeast requires a parser to succeed at least min times, consuming
+// as many repetitions as it can.
+func AtLeast(min int, p TextParser) TextParser {
+	return Between(min, -1, p)
+}
+
+// AtMost allows a parser to succeed up to max times, consuming as many
+// repetitions as it can. AtMost(0, p) matches exactly zero times and
+// leaves the input untouched.
+func AtMost(max int, p TextParser) TextParser {
+	return Between(0, max, p)
+}
+
+// SepBy matches zero or more occurrences of p separated by sep. The
+// token is the concatenation of every matched p and sep.
+func SepBy(p, sep TextParser) TextParser {
+	return Optional(SepBy1(p, sep))
+}
+
+// SepBy1 matches one or more occurrences of p separated by sep. The
+// token is the concatenation of every matched p and sep.
+func SepBy1(p, sep TextParser) TextParser {
+	return And(p, Optional(Repeat(And(sep, p))))
+}
+
+// BetweenPos is the TextParserPos equivalent of Between.
+func BetweenPos(min, max int, p TextParserPos) TextParserPos {
+	return func(in string, offset int) (string, string, int, error) {
+		tok, rem, pos := "", in, offset
+		count := 0
+		for max < 0 || count < max {
+			t, r, newPos, err := p(rem, pos)
+			if err != nil {
+				break
+			}
+			tok += t
+			rem = r
+			pos = newPos
+			count++
+		}
+		if count < min {
+			return "", in, offset, newParseError(pos, fmt.Sprintf("%d repetitions", count), fmt.Sprintf("at least %d repetitions", min))
+		}
+		return tok, rem, pos, nil
+	}
+}
+
+// TimesPos is the TextParserPos equivalent of Times.
+func TimesPos(n int, p TextParserPos) TextParserPos {
+	return BetweenPos(n, n, p)
+}
+
+// AtLeastPos is the TextParserPos equivalent of AtLeast.
+func AtLeastPos(min int, p TextParserPos) TextParserPos {
+	return BetweenPos(min, -1, p)
+}
+
+// AtMostPos is the TextParserPos equivalent of AtMost: AtMostPos(0, p)
+// matches exactly zero times and leaves the input untouched.
+func AtMostPos(max int, p TextParserPos) TextParserPos {
+	return BetweenPos(0, max, p)
+}
+
+// SepByPos is the TextParserPos equivalent of SepBy.
+func SepByPos(p, sep TextParserPos) TextParserPos {
+	return OptionalPos(SepBy1Pos(p, sep))
+}
+
+// SepBy1Pos is the TextParserPos equivalent of SepBy1.
+func SepBy1Pos(p, sep TextParserPos) TextParserPos {
+	return AndPos(p, OptionalPos(RepeatPos(AndPos(sep, p))))
+}
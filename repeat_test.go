@@ -0,0 +1,161 @@
+package goparsec
+
+import "testing"
+
+func TestBetween(t *testing.T) {
+	tests := []struct {
+		name    string
+		min     int
+		max     int
+		in      string
+		tok     string
+		rem     string
+		wantErr bool
+	}{
+		{"exact count", 2, 2, "aaab", "aa", "ab", false},
+		{"too few", 3, 3, "aab", "", "aab", true},
+		{"unbounded max", 1, -1, "aaab", "aaa", "b", false},
+		{"zero allowed", 0, 1, "b", "", "b", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tok, rem, err := Between(tt.min, tt.max, ExpectRune('a'))(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tok != tt.tok || rem != tt.rem {
+				t.Errorf("got (%q, %q), want (%q, %q)", tok, rem, tt.tok, tt.rem)
+			}
+		})
+	}
+}
+
+func TestTimes(t *testing.T) {
+	tok, rem, err := Times(3, ExpectRune('a'))("aaaa")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tok != "aaa" || rem != "a" {
+		t.Errorf("got (%q, %q), want (%q, %q)", tok, rem, "aaa", "a")
+	}
+
+	_, _, err = Times(3, ExpectRune('a'))("aa")
+	if err == nil {
+		t.Fatalf("expected an error when fewer than n repetitions are present")
+	}
+}
+
+func TestAtLeastAtMost(t *testing.T) {
+	tok, rem, err := AtLeast(2, ExpectRune('a'))("aaab")
+	if err != nil || tok != "aaa" || rem != "b" {
+		t.Errorf("AtLeast: got (%q, %q, %v)", tok, rem, err)
+	}
+
+	_, _, err = AtLeast(2, ExpectRune('a'))("ab")
+	if err == nil {
+		t.Errorf("AtLeast should fail when the minimum isn't met")
+	}
+
+	tok, rem, err = AtMost(2, ExpectRune('a'))("aaab")
+	if err != nil || tok != "aa" || rem != "ab" {
+		t.Errorf("AtMost: got (%q, %q, %v)", tok, rem, err)
+	}
+
+	tok, rem, err = AtMost(0, ExpectRune('a'))("aaab")
+	if err != nil || tok != "" || rem != "aaab" {
+		t.Errorf("AtMost(0, ...) should match zero repetitions: got (%q, %q, %v)", tok, rem, err)
+	}
+}
+
+func TestSepBy(t *testing.T) {
+	p := SepBy(ExpectDigit, ExpectByte(','))
+
+	tok, rem, err := p("1,2,3;")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tok != "1,2,3" || rem != ";" {
+		t.Errorf("got (%q, %q), want (%q, %q)", tok, rem, "1,2,3", ";")
+	}
+
+	tok, rem, err = p(";")
+	if err != nil {
+		t.Fatalf("SepBy should allow zero matches: %v", err)
+	}
+	if tok != "" || rem != ";" {
+		t.Errorf("got (%q, %q), want (%q, %q)", tok, rem, "", ";")
+	}
+}
+
+func TestSepBy1RequiresOneMatch(t *testing.T) {
+	p := SepBy1(ExpectDigit, ExpectByte(','))
+
+	_, _, err := p(";")
+	if err == nil {
+		t.Fatalf("SepBy1 should require at least one match")
+	}
+
+	tok, rem, err := p("1,2;")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tok != "1,2" || rem != ";" {
+		t.Errorf("got (%q, %q), want (%q, %q)", tok, rem, "1,2", ";")
+	}
+}
+
+func TestBetweenPosReportsPosition(t *testing.T) {
+	in := "aa\nb"
+	p := AndPos(BetweenPos(3, 3, ExpectBytePos('a')), ExpectBytePos('\n'))
+	_, _, _, err := p(in, 0)
+	pe, ok := err.(*ParseError)
+	if !ok {
+		t.Fatalf("expected a *ParseError, got %T", err)
+	}
+	pe.Resolve(in)
+	if pe.Line != 1 || pe.Col != 3 {
+		t.Errorf("got line %d col %d, want line 1 col 3", pe.Line, pe.Col)
+	}
+}
+
+func TestTimesPosAtLeastPosAtMostPos(t *testing.T) {
+	tok, rem, newOffset, err := TimesPos(3, ExpectBytePos('a'))("aaaa", 0)
+	if err != nil || tok != "aaa" || rem != "a" || newOffset != 3 {
+		t.Errorf("TimesPos: got (%q, %q, %d, %v)", tok, rem, newOffset, err)
+	}
+
+	tok, rem, newOffset, err = AtLeastPos(2, ExpectBytePos('a'))("aaab", 0)
+	if err != nil || tok != "aaa" || rem != "b" || newOffset != 3 {
+		t.Errorf("AtLeastPos: got (%q, %q, %d, %v)", tok, rem, newOffset, err)
+	}
+
+	tok, rem, newOffset, err = AtMostPos(2, ExpectBytePos('a'))("aaab", 0)
+	if err != nil || tok != "aa" || rem != "ab" || newOffset != 2 {
+		t.Errorf("AtMostPos: got (%q, %q, %d, %v)", tok, rem, newOffset, err)
+	}
+
+	tok, rem, newOffset, err = AtMostPos(0, ExpectBytePos('a'))("aaab", 0)
+	if err != nil || tok != "" || rem != "aaab" || newOffset != 0 {
+		t.Errorf("AtMostPos(0, ...) should match zero repetitions: got (%q, %q, %d, %v)", tok, rem, newOffset, err)
+	}
+}
+
+func TestSepByPosAndSepBy1Pos(t *testing.T) {
+	digit := FromTextParser(ExpectDigit)
+	comma := ExpectBytePos(',')
+
+	tok, rem, _, err := SepByPos(digit, comma)("1,2,3;", 0)
+	if err != nil || tok != "1,2,3" || rem != ";" {
+		t.Errorf("SepByPos: got (%q, %q, %v)", tok, rem, err)
+	}
+
+	tok, rem, _, err = SepByPos(digit, comma)(";", 0)
+	if err != nil || tok != "" || rem != ";" {
+		t.Errorf("SepByPos should allow zero matches: got (%q, %q, %v)", tok, rem, err)
+	}
+
+	_, _, _, err = SepBy1Pos(digit, comma)(";", 0)
+	if err == nil {
+		t.Fatalf("SepBy1Pos should require at least one match")
+	}
+}
@@ -0,0 +1,88 @@
+package goparsec
+
+import (
+	"fmt"
+	"unicode"
+	"unicode/utf8"
+)
+
+// RuneClass tests whether a rune belongs to a set of unicode ranges.
+type RuneClass struct {
+	tables []*unicode.RangeTable
+	pred   func(rune) bool
+}
+
+// NewRuneClass builds a RuneClass matching any rune in any of tables.
+func NewRuneClass(tables ...*unicode.RangeTable) RuneClass {
+	return RuneClass{tables: tables}
+}
+
+// Contains reports whether r belongs to the class.
+func (c RuneClass) Contains(r rune) bool {
+	for _, table := range c.tables {
+		if unicode.Is(table, r) {
+			return true
+		}
+	}
+	if c.pred != nil {
+		return c.pred(r)
+	}
+	return false
+}
+
+// Negate returns a RuneClass matching exactly the runes c does not.
+func Negate(c RuneClass) RuneClass {
+	return RuneClass{pred: func(r rune) bool { return !c.Contains(r) }}
+}
+
+// ExpectRuneIn expects the next rune to belong to any of the given
+// unicode range tables, e.g. ExpectRuneIn(unicode.Letter, unicode.Digit).
+func ExpectRuneIn(tables ...*unicode.RangeTable) TextParser {
+	return expectRuneClass(NewRuneClass(tables...))
+}
+
+// ExpectRuneNotIn expects the next rune to belong to none of the given
+// unicode range tables.
+func ExpectRuneNotIn(tables ...*unicode.RangeTable) TextParser {
+	return expectRuneClass(Negate(NewRuneClass(tables...)))
+}
+
+// ExpectRuneRange expects the next rune to fall in the inclusive range
+// [lo, hi].
+func ExpectRuneRange(lo, hi rune) TextParser {
+	return expectRuneClass(RuneClass{tables: []*unicode.RangeTable{{
+		R32: []unicode.Range32{{Lo: uint32(lo), Hi: uint32(hi), Stride: 1}},
+	}}})
+}
+
+func expectRuneClass(c RuneClass) TextParser {
+	return checkInputSize(func(in string) (string, string, error) {
+		r, size := utf8.DecodeRuneInString(in)
+		if !c.Contains(r) {
+			return "", in, fmt.Errorf("Expected rune in class, Got '%c'", r)
+		}
+		return in[:size], in[size:], nil
+	})
+}
+
+var (
+	// ExpectDigit expects any unicode digit, per unicode.IsDigit.
+	ExpectDigit = expectPredicate(unicode.IsDigit)
+
+	// ExpectLetter expects any unicode letter, per unicode.IsLetter.
+	ExpectLetter = expectPredicate(unicode.IsLetter)
+
+	// ExpectWhiteSpace expects any unicode whitespace character, per
+	// unicode.IsSpace.
+	ExpectWhiteSpace = expectPredicate(unicode.IsSpace)
+)
+
+func expectPredicate(pred func(rune) bool) TextParser {
+	return checkInputSize(func(in string) (string, string, error) {
+		r, size := utf8.DecodeRuneInString(in)
+		if !pred(r) {
+			return "", in, fmt.Errorf("Expected rune matching predicate, Got '%c'", r)
+		}
+		return in[:size], in[size:], nil
+	})
+}
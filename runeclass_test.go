@@ -0,0 +1,105 @@
+package goparsec
+
+import (
+	"testing"
+	"unicode"
+)
+
+func TestExpectDigitUnicode(t *testing.T) {
+	tok, rem, err := ExpectDigit("٣abc") // Arabic-Indic digit three
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tok != "٣" || rem != "abc" {
+		t.Errorf("got (%q, %q), want (%q, %q)", tok, rem, "٣", "abc")
+	}
+}
+
+func TestExpectLetterUnicode(t *testing.T) {
+	tok, rem, err := ExpectLetter("日abc")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tok != "日" || rem != "abc" {
+		t.Errorf("got (%q, %q), want (%q, %q)", tok, rem, "日", "abc")
+	}
+}
+
+func TestExpectRuneIn(t *testing.T) {
+	p := ExpectRuneIn(unicode.Letter, unicode.Digit)
+
+	for _, in := range []string{"a1", "9a", "日a"} {
+		_, _, err := p(in)
+		if err != nil {
+			t.Errorf("ExpectRuneIn(%q) returned an error: %v", in, err)
+		}
+	}
+
+	_, _, err := p("!a")
+	if err == nil {
+		t.Errorf("ExpectRuneIn should reject '!'")
+	}
+}
+
+func TestExpectRuneNotIn(t *testing.T) {
+	p := ExpectRuneNotIn(unicode.Digit)
+
+	tok, rem, err := p("a1")
+	if err != nil || tok != "a" || rem != "1" {
+		t.Errorf("got (%q, %q, %v)", tok, rem, err)
+	}
+
+	_, _, err = p("1a")
+	if err == nil {
+		t.Errorf("ExpectRuneNotIn should reject a digit")
+	}
+}
+
+func TestExpectRuneRange(t *testing.T) {
+	p := ExpectRuneRange('a', 'f')
+
+	tok, rem, err := p("c123")
+	if err != nil || tok != "c" || rem != "123" {
+		t.Errorf("got (%q, %q, %v)", tok, rem, err)
+	}
+
+	_, _, err = p("g")
+	if err == nil {
+		t.Errorf("ExpectRuneRange should reject 'g'")
+	}
+}
+
+func TestNegate(t *testing.T) {
+	vowels := NewRuneClass()
+	vowels.pred = func(r rune) bool {
+		switch r {
+		case 'a', 'e', 'i', 'o', 'u':
+			return true
+		}
+		return false
+	}
+	notVowels := Negate(vowels)
+
+	if notVowels.Contains('a') {
+		t.Errorf("Negate should reject what the source class accepts")
+	}
+	if !notVowels.Contains('b') {
+		t.Errorf("Negate should accept what the source class rejects")
+	}
+}
+
+func BenchmarkExpectRuneIn(b *testing.B) {
+	p := ExpectRuneIn(unicode.Letter, unicode.Digit, unicode.Punct)
+	for n := 0; n < b.N; n++ {
+		_, _, err := p("x")
+		_ = err
+	}
+}
+
+func BenchmarkExpectRuneFrom_LargeSet(b *testing.B) {
+	p := ExpectRuneFrom("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789")
+	for n := 0; n < b.N; n++ {
+		_, _, err := p("x")
+		_ = err
+	}
+}